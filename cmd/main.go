@@ -41,7 +41,8 @@ func main() {
 	// Создаем пайплайн и корневой контекст для него
 	p, ctx := pipeline.New(
 		context.Background(),
-		pipeline.WithLogger(logger), // Передаем наш опциональный логгер
+		pipeline.WithLogger(logger),                 // Передаем наш опциональный логгер
+		pipeline.WithShutdownTimeout(5*time.Second), // Если после Ctrl-C воркеры не уложились в 5с, не ждем дальше
 	)
 
 	log.Printf("Запуск сканирования директории '%s' с %d воркерами...\n", *dir, *workers)
@@ -71,35 +72,45 @@ func main() {
 			out <- path // Отправляем путь к файлу в канал
 			return nil
 		})
-	})
+	}, pipeline.WithName("walk"))
 
 	// Мы берем канал с путями `pathsChan`, указываем количество воркеров
 	// и передаем функцию, которая вычисляет MD5 для одного файла.
-	resultsChan := pipeline.FanOut(ctx, p, pathsChan, *workers, func(path string) (Result, error) {
+	// Нечитаемый файл не должен обрывать сканирование всей директории, поэтому
+	// используем SkipOnError - такие файлы попадут в failedChan.
+	resultsChan, failedChan := pipeline.FanOut(ctx, p, pathsChan, *workers, func(path string) (Result, error) {
 		file, err := os.Open(path)
 		if err != nil {
-			// Ошибка открытия файла - критична. Останавливаем весь пайплайн.
 			return Result{}, fmt.Errorf("не удалось открыть файл %s: %w", path, err)
 		}
 		defer file.Close()
 
 		hash := md5.New()
 		if _, err := io.Copy(hash, file); err != nil {
-			// Ошибка чтения критична
 			return Result{}, fmt.Errorf("не удалось прочитать файл %s: %w", path, err)
 		}
 
 		return Result{Path: path, Hash: hex.EncodeToString(hash.Sum(nil))}, nil
-	})
+	}, pipeline.WithErrorPolicy(pipeline.SkipOnError()), pipeline.WithName("hash"))
 
 	// Берем канал с результатами `resultsChan` и передаем функцию,
 	// которая просто печатает каждый результат в консоль.
 	pipeline.Sink(ctx, p, resultsChan, func(res Result) {
 		fmt.Printf("%s  %s\n", res.Hash, res.Path)
-	})
-
-	// Ожидание завершения пайплайна
-	p.Wait()
+	}, pipeline.WithName("print"))
+
+	// Файлы, которые не удалось прочитать, просто логируем вместо падения всей утилиты.
+	pipeline.Sink(ctx, p, failedChan, func(f pipeline.FailedItem[string]) {
+		log.Printf("пропущен файл: %v\n", f.Err)
+	}, pipeline.WithName("failed"))
+
+	// Ожидаем штатного завершения пайплайна либо Ctrl-C: по первому сигналу
+	// воркеры дорабатывают то, что уже в полете, и печатают частичные результаты;
+	// если не укладываются в WithShutdownTimeout (или придет второй Ctrl-C),
+	// завершаемся, не дожидаясь остальных.
+	if err := pipeline.RunWithSignals(ctx, p, os.Interrupt); err != nil {
+		log.Printf("пайплайн не завершился штатно: %v\n", err)
+	}
 
 	log.Printf("Работа завершена за %v.\n", time.Since(startTime))
 
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// Batch накапливает элементы из `in` в срезы по `size` штук и отправляет их в выходной
+// канал. Если за время `flushInterval` не набралось `size` элементов, накопленный
+// неполный батч отправляется досрочно (флаш по таймауту). flushInterval <= 0 отключает
+// флаш по таймауту — батч будет отправлен только когда наберется `size` элементов или
+// когда закроется `in`.
+func Batch[T any](ctx context.Context, p *Pipeline, in <-chan T, size int, flushInterval time.Duration, opts ...NodeOption) <-chan []T {
+	if size <= 0 {
+		size = 1
+	}
+
+	cfg := applyNodeOptions(opts)
+	out := make(chan []T, cfg.bufferSize)
+
+	p.Add(func() {
+		defer close(out)
+
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if flushInterval > 0 {
+			timer = time.NewTimer(flushInterval)
+			defer timer.Stop()
+			timerC = timer.C
+		}
+
+		batch := make([]T, 0, size)
+
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			sent := batch
+			ok := sendWithOverflow(ctx, cfg.consumerGone, out, sent, cfg.overflow)
+			batch = make([]T, 0, size)
+			return ok
+		}
+
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+
+				batch = append(batch, item)
+				if len(batch) >= size {
+					if !flush() {
+						return
+					}
+					if timer != nil {
+						if !timer.Stop() {
+							<-timer.C
+						}
+						timer.Reset(flushInterval)
+					}
+				}
+
+			case <-timerC:
+				if !flush() {
+					return
+				}
+				timer.Reset(flushInterval)
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return out
+}
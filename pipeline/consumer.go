@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// ConsumerGone - это сигнал отмены, отдельный от Pipeline.Stop: он позволяет
+// медленному или более не заинтересованному потребителю (Sink) сообщить
+// производителям выше по потоку (Source, FanOut), что можно прекратить
+// генерацию данных, не считая это ошибкой пайплайна. Идея аналогична паре
+// WatchClose/WatchConsumerGone из лог-вотчера в Moby: "потребитель ушел" -
+// это не то же самое, что "пайплайн упал с ошибкой".
+type ConsumerGone struct {
+	done chan struct{}
+	once sync.Once
+}
+
+// NewConsumerGone создает новый, еще не сработавший сигнал.
+func NewConsumerGone() *ConsumerGone {
+	return &ConsumerGone{done: make(chan struct{})}
+}
+
+// Signal сообщает, что потребителю больше не нужны данные. Безопасно вызывать
+// несколько раз - сработает только один раз.
+func (c *ConsumerGone) Signal() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Done возвращает канал, закрывающийся при вызове Signal.
+func (c *ConsumerGone) Done() <-chan struct{} {
+	return c.done
+}
+
+// IsSignaled сообщает, был ли уже вызван Signal, без блокировки. Полезно для
+// продюсеров вроде Source, которые сами управляют своим циклом генерации
+// (например, filepath.Walk) и не могут напрямую участвовать в select.
+func (c *ConsumerGone) IsSignaled() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// consumerGoneChan nil-безопасно достает канал готовности из ConsumerGone:
+// nil-источник дает nil-канал, который в select никогда не сработает.
+func consumerGoneChan(c *ConsumerGone) <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.Done()
+}
+
+// SinkWithCancel ведет себя как Sink, но дополнительно возвращает функцию,
+// вызов которой сигнализирует через ConsumerGone (см. WithConsumerGone), что
+// потребителю больше не нужны данные. Узлы выше по потоку, которым передан
+// тот же ConsumerGone, прекращают генерацию/обработку без остановки всего
+// пайплайна через p.Stop.
+func SinkWithCancel[T any](ctx context.Context, p *Pipeline, in <-chan T, fn func(item T), opts ...NodeOption) func() {
+	cfg := applyNodeOptions(opts)
+	gone := cfg.consumerGone
+	if gone == nil {
+		gone = NewConsumerGone()
+	}
+
+	Sink(ctx, p, in, fn, opts...)
+
+	return gone.Signal
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSinkWithCancelSignalsUpstream проверяет связку SinkWithCancel +
+// WithConsumerGone: как только потребитель вызывает возвращенную функцию,
+// Source выше по потоку перестает генерировать данные и штатно завершается,
+// не считая это ошибкой пайплайна (p.Stop ни разу не вызывается).
+func TestSinkWithCancelSignalsUpstream(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	gone := NewConsumerGone()
+
+	var produced int
+	src := Source(ctx, p, func(out chan<- int) {
+		for i := 0; ; i++ {
+			select {
+			case out <- i:
+				produced++
+			case <-ctx.Done():
+				return
+			case <-gone.Done():
+				return
+			}
+		}
+	}, WithConsumerGone(gone))
+
+	var received []int
+	cancel := SinkWithCancel(ctx, p, src, func(item int) {
+		received = append(received, item)
+	}, WithConsumerGone(gone))
+
+	// Забираем пару элементов, затем теряем интерес.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("p.Wait() не вернулся после вызова cancel() от SinkWithCancel")
+	}
+
+	if ctx.Err() != nil {
+		t.Fatalf("ConsumerGone не должен отменять ctx пайплайна, но ctx.Err() = %v", ctx.Err())
+	}
+	if len(received) == 0 {
+		t.Fatal("ожидали, что Sink успеет получить хотя бы один элемент до отмены")
+	}
+}
+
+// TestSinkWithCancelSignalIsIdempotent проверяет, что повторный вызов функции
+// отмены, возвращенной SinkWithCancel, безопасен (ConsumerGone.Signal - once).
+func TestSinkWithCancelSignalIsIdempotent(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	close(in)
+
+	cancel := SinkWithCancel(ctx, p, in, func(int) {})
+
+	cancel()
+	cancel()
+
+	p.Wait()
+}
@@ -0,0 +1,224 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Tee разбирает один входной канал на n независимых выходных каналов, в каждый из
+// которых попадает КАЖДЫЙ элемент `in` (в отличие от FanOut, где элемент достается
+// только одному воркеру). Полезно, когда один и тот же поток нужно одновременно
+// обработать несколькими независимыми способами (например, OCR + анализ).
+func Tee[T any](ctx context.Context, p *Pipeline, in <-chan T, n int, opts ...NodeOption) []<-chan T {
+	cfg := applyNodeOptions(opts)
+
+	outs := make([]chan T, n)
+	branches := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T, cfg.bufferSize)
+		branches[i] = outs[i]
+	}
+
+	p.Add(func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+
+				for _, out := range outs {
+					select {
+					case out <- item:
+					case <-ctx.Done():
+						return
+					case <-consumerGoneChan(cfg.consumerGone):
+						return
+					}
+				}
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return branches
+}
+
+// Merge сводит несколько входных каналов в один: запускает по одной горутине на
+// каждый вход и закрывает выходной канал после того, как все входы исчерпаны.
+func Merge[T any](ctx context.Context, p *Pipeline, ins []<-chan T, opts ...NodeOption) <-chan T {
+	cfg := applyNodeOptions(opts)
+	out := make(chan T, cfg.bufferSize)
+
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		in := in
+		wg.Add(1)
+		p.Add(func() {
+			defer wg.Done()
+			for {
+				select {
+				case item, ok := <-in:
+					if !ok {
+						return
+					}
+
+					if !sendWithOverflow(ctx, cfg.consumerGone, out, item, cfg.overflow) {
+						return
+					}
+
+				case <-ctx.Done():
+					return
+
+				case <-consumerGoneChan(cfg.consumerGone):
+					return
+				}
+			}
+		})
+	}
+
+	p.Add(func() {
+		wg.Wait()
+		close(out)
+	})
+
+	return out
+}
+
+// joinEntry хранит значение, ожидающее пары в JoinByKey, вместе с временем, когда
+// оно было добавлено в буфер (для TTL-вытеснения).
+type joinEntry[T any] struct {
+	value      T
+	insertedAt time.Time
+}
+
+// JoinByKey читает из двух независимых потоков `left` и `right`, извлекает из
+// каждого элемента ключ (`keyOfLeft`/`keyOfRight`) и буферизует элементы, для
+// которых пара с того же ключа еще не пришла. Как только для ключа находятся обе
+// половины, вызывается `join` и результат отправляется в выходной канал.
+//
+// Для каждого ключа буфер - это очередь FIFO, а не одно значение: если на одной
+// стороне приходит несколько элементов с одинаковым ключом раньше, чем появится
+// пара, они не перезаписывают друг друга, а ждут своей очереди и разбираются в
+// том порядке, в котором пришли.
+//
+// Буфер ограничен по памяти параметром ttl: раз в ttl непарные элементы старше ttl
+// вытесняются из буфера. ttl <= 0 отключает вытеснение (буфер не ограничен).
+func JoinByKey[A, B any, K comparable, Out any](
+	ctx context.Context,
+	p *Pipeline,
+	left <-chan A,
+	right <-chan B,
+	keyOfLeft func(A) K,
+	keyOfRight func(B) K,
+	join func(a A, b B) Out,
+	ttl time.Duration,
+	opts ...NodeOption,
+) <-chan Out {
+	cfg := applyNodeOptions(opts)
+	out := make(chan Out, cfg.bufferSize)
+
+	p.Add(func() {
+		defer close(out)
+
+		pendingLeft := make(map[K][]joinEntry[A])
+		pendingRight := make(map[K][]joinEntry[B])
+
+		var evictC <-chan time.Time
+		if ttl > 0 {
+			ticker := time.NewTicker(ttl)
+			defer ticker.Stop()
+			evictC = ticker.C
+		}
+
+		leftCh, rightCh := left, right
+
+		for leftCh != nil || rightCh != nil {
+			select {
+			case a, ok := <-leftCh:
+				if !ok {
+					leftCh = nil
+					continue
+				}
+
+				k := keyOfLeft(a)
+				if queue := pendingRight[k]; len(queue) > 0 {
+					b := queue[0]
+					if len(queue) == 1 {
+						delete(pendingRight, k)
+					} else {
+						pendingRight[k] = queue[1:]
+					}
+					if !sendWithOverflow(ctx, cfg.consumerGone, out, join(a, b.value), cfg.overflow) {
+						return
+					}
+				} else {
+					pendingLeft[k] = append(pendingLeft[k], joinEntry[A]{value: a, insertedAt: time.Now()})
+				}
+
+			case b, ok := <-rightCh:
+				if !ok {
+					rightCh = nil
+					continue
+				}
+
+				k := keyOfRight(b)
+				if queue := pendingLeft[k]; len(queue) > 0 {
+					a := queue[0]
+					if len(queue) == 1 {
+						delete(pendingLeft, k)
+					} else {
+						pendingLeft[k] = queue[1:]
+					}
+					if !sendWithOverflow(ctx, cfg.consumerGone, out, join(a.value, b), cfg.overflow) {
+						return
+					}
+				} else {
+					pendingRight[k] = append(pendingRight[k], joinEntry[B]{value: b, insertedAt: time.Now()})
+				}
+
+			case now := <-evictC:
+				evictStale(pendingLeft, now, ttl)
+				evictStale(pendingRight, now, ttl)
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// evictStale вычищает из каждой очереди pending записи старше ttl, сохраняя
+// относительный порядок оставшихся, и удаляет ключ целиком, если очередь опустела.
+func evictStale[K comparable, T any](pending map[K][]joinEntry[T], now time.Time, ttl time.Duration) {
+	for k, queue := range pending {
+		kept := queue[:0]
+		for _, e := range queue {
+			if now.Sub(e.insertedAt) <= ttl {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(pending, k)
+		} else {
+			pending[k] = kept
+		}
+	}
+}
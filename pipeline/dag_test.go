@@ -0,0 +1,264 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestJoinByKeyTTLEvictsUnmatched проверяет, что непарные элементы вытесняются
+// из буфера JoinByKey по истечении ttl и больше не участвуют в джойне, даже
+// если их пара приходит позже.
+func TestJoinByKeyTTLEvictsUnmatched(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	left := make(chan string)
+	right := make(chan string)
+
+	out := JoinByKey(ctx, p, left, right,
+		func(a string) string { return a },
+		func(b string) string { return b },
+		func(a, b string) string { return a + "+" + b },
+		30*time.Millisecond,
+	)
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		// "stale" не дождется пары в течение ttl и будет вытеснен.
+		left <- "stale"
+		time.Sleep(80 * time.Millisecond)
+
+		// Пара для "stale" приходит с опозданием и не должна с ним соединиться.
+		right <- "stale"
+
+		// "fresh" приходит после вытеснения "stale" и должен успешно
+		// присоединиться к своей паре.
+		right <- "fresh"
+		left <- "fresh"
+	}()
+
+	select {
+	case got, ok := <-out:
+		if !ok {
+			t.Fatalf("канал закрылся раньше, чем дождались результата для \"fresh\"")
+		}
+		if got != "fresh+fresh" {
+			t.Fatalf("получили %q, ожидали \"fresh+fresh\" (stale должен был быть вытеснен по ttl)", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("не дождались результата джойна для \"fresh\"")
+	}
+
+	p.Stop(nil)
+	p.Wait()
+}
+
+// TestJoinByKeyDuplicateKeyFIFO проверяет, что несколько непарных элементов с
+// одинаковым ключом на одной стороне не перезаписывают друг друга: они
+// складываются в очередь и разбираются в порядке прихода, когда пары находятся.
+func TestJoinByKeyDuplicateKeyFIFO(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	left := make(chan string)
+	right := make(chan string)
+
+	out := JoinByKey(ctx, p, left, right,
+		func(a string) string { return "k" },
+		func(b string) string { return "k" },
+		func(a, b string) string { return a + "+" + b },
+		0,
+	)
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		// Оба элемента на одном и том же ключе "k" должны дождаться своей пары
+		// по отдельности, а не перезаписать друг друга в буфере.
+		left <- "a1"
+		left <- "a2"
+		right <- "b1"
+		right <- "b2"
+	}()
+
+	var got []string
+	for v := range out {
+		got = append(got, v)
+	}
+
+	want := []string{"a1+b1", "a2+b2"}
+	if len(got) != len(want) {
+		t.Fatalf("получили %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("получили %v, ожидали %v (должен быть FIFO по ключу)", got, want)
+		}
+	}
+
+	p.Wait()
+}
+
+// TestJoinByKeyWithoutTTLKeepsWaiting проверяет, что при ttl <= 0 вытеснение
+// отключено: непарный элемент остается в буфере сколько угодно долго и все
+// равно присоединяется к паре, пришедшей с опозданием.
+func TestJoinByKeyWithoutTTLKeepsWaiting(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	left := make(chan string)
+	right := make(chan string)
+
+	out := JoinByKey(ctx, p, left, right,
+		func(a string) string { return a },
+		func(b string) string { return b },
+		func(a, b string) string { return a + "+" + b },
+		0,
+	)
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- "k"
+		time.Sleep(80 * time.Millisecond)
+		right <- "k"
+	}()
+
+	select {
+	case got, ok := <-out:
+		if !ok || got != "k+k" {
+			t.Fatalf("получили (%q, %v), ожидали (\"k+k\", true)", got, ok)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("не дождались результата джойна без ttl")
+	}
+
+	p.Stop(nil)
+	p.Wait()
+}
+
+// TestTeeDeliversEveryItemToEveryBranch проверяет, что Tee рассылает каждый
+// элемент `in` во все выходные ветки, а не только одной из них (в отличие от
+// FanOut).
+func TestTeeDeliversEveryItemToEveryBranch(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+	}()
+
+	branches := Tee(ctx, p, in, 3)
+	if len(branches) != 3 {
+		t.Fatalf("ожидали 3 ветки, получили %d", len(branches))
+	}
+
+	results := make([][]int, len(branches))
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		i, branch := i, branch
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range branch {
+				results[i] = append(results[i], v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := []int{0, 1, 2, 3, 4}
+	for i, got := range results {
+		if len(got) != len(want) {
+			t.Fatalf("ветка %d: получили %v, ожидали %v", i, got, want)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("ветка %d: получили %v, ожидали %v", i, got, want)
+			}
+		}
+	}
+
+	p.Wait()
+}
+
+// TestMergeFansInAllInputs проверяет, что Merge собирает в один канал все
+// элементы со всех входов и закрывает выход только после того, как исчерпаны
+// все входные каналы.
+func TestMergeFansInAllInputs(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	a := make(chan int)
+	b := make(chan int)
+	c := make(chan int)
+
+	go func() {
+		defer close(a)
+		a <- 1
+		a <- 2
+	}()
+	go func() {
+		defer close(b)
+		b <- 3
+	}()
+	go func() {
+		defer close(c)
+		c <- 4
+		c <- 5
+	}()
+
+	out := Merge(ctx, p, []<-chan int{a, b, c})
+
+	seen := make(map[int]bool)
+	for v := range out {
+		seen[v] = true
+	}
+
+	for _, want := range []int{1, 2, 3, 4, 5} {
+		if !seen[want] {
+			t.Fatalf("не получили значение %d через Merge, видели %v", want, seen)
+		}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("получили %d уникальных значений, ожидали 5 (%v)", len(seen), seen)
+	}
+
+	p.Wait()
+}
+
+// TestMergeRespectsBufferAndConsumerGone проверяет, что Merge (как и Tee,
+// JoinByKey) принимает NodeOption: WithBuffer задает буферизацию выходного
+// канала, а сигнал ConsumerGone останавливает все горутины слияния, не
+// дожидаясь, пока опустеют входные каналы.
+func TestMergeRespectsBufferAndConsumerGone(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	a := make(chan int)
+	b := make(chan int)
+
+	gone := NewConsumerGone()
+	out := Merge(ctx, p, []<-chan int{a, b}, WithBuffer(2), WithConsumerGone(gone))
+
+	a <- 1
+	gone.Signal()
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("p.Wait() не вернулся после ConsumerGone.Signal() - горутины Merge зависли")
+	}
+
+	<-out
+}
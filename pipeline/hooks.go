@@ -0,0 +1,63 @@
+package pipeline
+
+// WithOnItem задает хук, вызываемый узлом при получении n элементов на вход, до их
+// обработки (сейчас n
+// всегда 1 - счетчик оставлен для единообразия с остальными хуками и будущих
+// пакетных узлов). Позволяет, например, инкрементировать счетчик Prometheus без
+// форка пайплайна.
+func WithOnItem(fn func(stage string, n int)) Option {
+	return func(p *Pipeline) {
+		p.onItem = fn
+	}
+}
+
+// WithOnError задает хук, вызываемый узлом при ошибке воркера уже после применения
+// ErrorPolicy/Classifier: один раз на элемент, который был пропущен или остановил
+// пайплайн. Ошибки попыток, после которых последовал повтор, в хук не попадают.
+func WithOnError(fn func(stage string, err error)) Option {
+	return func(p *Pipeline) {
+		p.onError = fn
+	}
+}
+
+// WithOnStageStart задает хук, вызываемый при запуске узла.
+func WithOnStageStart(fn func(stage string)) Option {
+	return func(p *Pipeline) {
+		p.onStageStart = fn
+	}
+}
+
+// WithOnStageStop задает хук, вызываемый при остановке узла.
+func WithOnStageStop(fn func(stage string)) Option {
+	return func(p *Pipeline) {
+		p.onStageStop = fn
+	}
+}
+
+func (p *Pipeline) fireItem(stage string, n int) {
+	if p.onItem != nil {
+		p.onItem(stage, n)
+	}
+	p.logger.Printf("[%s] обработано элементов: %d", stage, n)
+}
+
+func (p *Pipeline) fireError(stage string, err error) {
+	if p.onError != nil {
+		p.onError(stage, err)
+	}
+	p.logger.Printf("[%s] ошибка: %v", stage, err)
+}
+
+func (p *Pipeline) fireStageStart(stage string) {
+	if p.onStageStart != nil {
+		p.onStageStart(stage)
+	}
+	p.logger.Printf("[%s] старт", stage)
+}
+
+func (p *Pipeline) fireStageStop(stage string) {
+	if p.onStageStop != nil {
+		p.onStageStop(stage)
+	}
+	p.logger.Printf("[%s] остановлен", stage)
+}
@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestLifecycleHooksAndStats проверяет, что WithOnItem/WithOnError/
+// WithOnStageStart/WithOnStageStop вызываются для каждой стадии с ее
+// стабильным именем (WithName), а Pipeline.Stats() отдает согласованные с
+// этими вызовами счетчики In/Out/Errors по завершении пайплайна.
+func TestLifecycleHooksAndStats(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		items   = map[string]int{}
+		errs    = map[string]int{}
+		started = map[string]int{}
+		stopped = map[string]int{}
+	)
+
+	p, ctx := New(context.Background(),
+		WithOnItem(func(stage string, n int) {
+			mu.Lock()
+			defer mu.Unlock()
+			items[stage] += n
+		}),
+		WithOnError(func(stage string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs[stage]++
+		}),
+		WithOnStageStart(func(stage string) {
+			mu.Lock()
+			defer mu.Unlock()
+			started[stage]++
+		}),
+		WithOnStageStop(func(stage string) {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped[stage]++
+		}),
+	)
+
+	src := Source(ctx, p, func(out chan<- int) {
+		for i := 0; i < 3; i++ {
+			out <- i
+		}
+	}, WithName("src"))
+
+	out, failed := FanOut(ctx, p, src, 2, func(item int) (int, error) {
+		if item == 1 {
+			return 0, errors.New("boom")
+		}
+		return item, nil
+	}, WithErrorPolicy(SkipOnError()), WithName("work"))
+
+	var sum int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for v := range out {
+			atomic.AddInt64(&sum, int64(v))
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range failed {
+		}
+	}()
+
+	wg.Wait()
+	p.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if started["src"] == 0 || stopped["src"] == 0 {
+		t.Fatalf("ожидали OnStageStart/OnStageStop для стадии \"src\", получили start=%d stop=%d", started["src"], stopped["src"])
+	}
+	if started["work"] == 0 || stopped["work"] == 0 {
+		t.Fatalf("ожидали OnStageStart/OnStageStop для стадии \"work\", получили start=%d stop=%d", started["work"], stopped["work"])
+	}
+	if items["src"] != 3 {
+		t.Fatalf("ожидали OnItem(\"src\", ...) в сумме 3, получили %d", items["src"])
+	}
+	if items["work"] != 3 {
+		t.Fatalf("ожидали OnItem(\"work\", ...) в сумме 3 (все элементы дошли до воркера), получили %d", items["work"])
+	}
+	if errs["work"] != 1 {
+		t.Fatalf("ожидали ровно одну OnError(\"work\", ...) для элемента 1, получили %d", errs["work"])
+	}
+
+	stats := p.Stats()
+	workStats, ok := stats["work"]
+	if !ok {
+		t.Fatalf("Stats() не содержит стадию \"work\": %+v", stats)
+	}
+	if workStats.In != 3 {
+		t.Fatalf("work.In = %d, ожидали 3", workStats.In)
+	}
+	if workStats.Out != 2 {
+		t.Fatalf("work.Out = %d, ожидали 2 (один элемент пропущен по SkipOnError)", workStats.Out)
+	}
+	if workStats.Errors != 1 {
+		t.Fatalf("work.Errors = %d, ожидали 1", workStats.Errors)
+	}
+
+	srcStats, ok := stats["src"]
+	if !ok {
+		t.Fatalf("Stats() не содержит стадию \"src\": %+v", stats)
+	}
+	if srcStats.In != 3 || srcStats.Out != 3 {
+		t.Fatalf("src stats = %+v, ожидали In=3 Out=3", srcStats)
+	}
+}
+
+// TestStageNameAutoGeneratesWhenUnset проверяет, что без WithName стадии
+// получают стабильные авто-имена вида "<kind>-<N>", уникальные в рамках
+// пайплайна, и что именно под этими именами появляются записи в Stats().
+func TestStageNameAutoGeneratesWhenUnset(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	close(in)
+	Sink(ctx, p, in, func(int) {})
+
+	p.Wait()
+
+	stats := p.Stats()
+	if _, ok := stats["sink-1"]; !ok {
+		t.Fatalf("ожидали авто-имя \"sink-1\" в Stats(), получили %+v", stats)
+	}
+}
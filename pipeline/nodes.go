@@ -3,33 +3,87 @@ package pipeline
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 )
 
 // Source запускает генератор `fn`, который производит данные и отправляет их в выходной канал.
 // Это узел-источник, у него нет входов.
 // Тип [T any] делает эту функцию типобезопасной.
-func Source[T any](ctx context.Context, p *Pipeline, fn func(out chan<- T)) <-chan T {
-	out := make(chan T)
+func Source[T any](ctx context.Context, p *Pipeline, fn func(out chan<- T), opts ...NodeOption) <-chan T {
+	cfg := applyNodeOptions(opts)
+	name := stageName(p, cfg, "source")
+	stats := p.stage(name)
+
+	produced := make(chan T)
+	out := make(chan T, cfg.bufferSize)
+
+	p.Add(func() {
+		// После завершения работы генератора, внутренний канал нужно закрыть -
+		// это сигнал пересылающей горутине ниже, что данные закончились.
+		defer close(produced)
+		fn(produced)
+	})
+
 	p.Add(func() {
-		// После завершения работы генератора, канал-выход нужно закрыть.
-		// Это будет сигналом для следующих узлов, что данные закончились.
-		defer close(out)
-		fn(out)
+		p.fireStageStart(name)
+		defer func() {
+			close(out)
+			p.fireStageStop(name)
+		}()
+
+		for {
+			select {
+			case item, ok := <-produced:
+				if !ok {
+					return
+				}
+
+				atomic.AddInt64(&stats.In, 1)
+				p.fireItem(name, 1)
+
+				if !sendWithOverflow(ctx, cfg.consumerGone, out, item, cfg.overflow) {
+					return
+				}
+				atomic.AddInt64(&stats.Out, 1)
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
 	})
+
 	return out
 }
 
 // FanOut запускает `workerCount` параллельных воркеров, которые читают данные
 // из входного канала `in`, обрабатывают их с помощью функции `fn` и отправляют
 // результат в выходной канал.
-func FanOut[In, Out any](ctx context.Context, p *Pipeline, in <-chan In, workerCount int, fn func(item In) (Out, error)) <-chan Out {
-	out := make(chan Out)
+//
+// По умолчанию (без опций) любая ошибка `fn` останавливает весь пайплайн - как и
+// раньше. WithErrorPolicy/WithClassifier позволяют вместо этого пропускать
+// проблемные элементы или повторять их с backoff; такие элементы попадают во
+// второй возвращаемый канал для dead-letter обработки.
+func FanOut[In, Out any](ctx context.Context, p *Pipeline, in <-chan In, workerCount int, fn func(item In) (Out, error), opts ...NodeOption) (<-chan Out, <-chan FailedItem[In]) {
+	cfg := applyNodeOptions(opts)
+	name := stageName(p, cfg, "fanout")
+	stats := p.stage(name)
+
+	out := make(chan Out, cfg.bufferSize)
+	failed := make(chan FailedItem[In], cfg.bufferSize)
+
+	metrics := &FanOutMetrics{}
+	p.registerFanOutMetrics(metrics)
 
 	// Эта WaitGroup нужна, чтобы дождаться завершения всех воркеров,
-	// прежде чем закрывать выходной канал `out`.
+	// прежде чем закрывать выходные каналы.
 	var wg sync.WaitGroup
 
 	p.Add(func() {
+		p.fireStageStart(name)
+
 		for i := 0; i < workerCount; i++ {
 			wg.Add(1)
 			p.Add(func() { // Каждый воркер - отдельная задача для пайплайна
@@ -41,42 +95,76 @@ func FanOut[In, Out any](ctx context.Context, p *Pipeline, in <-chan In, workerC
 							// Входной канал закрыт, воркеру больше нечего делать.
 							return
 						}
-						// Обрабатываем данные.
-						result, err := fn(item)
-						if err != nil {
-							// В случае ошибки останавливаем весь пайплайн.
-							p.Stop(err)
+
+						atomic.AddInt64(&stats.In, 1)
+						p.fireItem(name, 1)
+						atomic.AddInt64(&stats.InFlight, 1)
+
+						result, failedItem, fatal := runWithPolicy(ctx, item, fn, cfg.errorPolicy, cfg.classifier, metrics)
+
+						atomic.AddInt64(&stats.InFlight, -1)
+
+						if fatal != nil {
+							atomic.AddInt64(&stats.Errors, 1)
+							p.fireError(name, fatal)
+							// ActionFatal - останавливаем весь пайплайн, как и раньше.
+							p.Stop(fatal)
 							return
 						}
+						if failedItem != nil {
+							atomic.AddInt64(&stats.Errors, 1)
+							p.fireError(name, failedItem.Err)
+							select {
+							case failed <- *failedItem:
+							case <-ctx.Done():
+								return
+							case <-consumerGoneChan(cfg.consumerGone):
+								return
+							}
+							continue
+						}
 
-						// Отправляем результат дальше, но также проверяем на отмену.
-						select {
-						case out <- result:
-						case <-ctx.Done():
-							// Пайплайн был остановлен, пока мы обрабатывали данные.
+						// Отправляем результат дальше, учитывая overflow-политику и
+						// сигналы отмены/ухода потребителя.
+						if !sendWithOverflow(ctx, cfg.consumerGone, out, result, cfg.overflow) {
 							return
 						}
+						atomic.AddInt64(&stats.Out, 1)
 
 					case <-ctx.Done():
 						// Пайплайн был остановлен. Завершаем работу.
 						return
+
+					case <-consumerGoneChan(cfg.consumerGone):
+						// Потребитель сообщил, что данные больше не нужны - это не
+						// ошибка, просто прекращаем читать вход.
+						return
 					}
 				}
 			})
 		}
 
-		// Ожидаем завершения всех воркеров и только потом закрываем выходной канал.
+		// Ожидаем завершения всех воркеров и только потом закрываем выходные каналы.
 		wg.Wait()
 		close(out)
+		close(failed)
+		p.fireStageStop(name)
 	})
 
-	return out
+	return out, failed
 }
 
 // Sink запускает потребителя, который читает данные из канала `in` и выполняет над ними
 // финальную операцию `fn`. Это узел-приемник, у него нет выходов.
-func Sink[T any](ctx context.Context, p *Pipeline, in <-chan T, fn func(item T)) {
+func Sink[T any](ctx context.Context, p *Pipeline, in <-chan T, fn func(item T), opts ...NodeOption) {
+	cfg := applyNodeOptions(opts)
+	name := stageName(p, cfg, "sink")
+	stats := p.stage(name)
+
 	p.Add(func() {
+		p.fireStageStart(name)
+		defer p.fireStageStop(name)
+
 		for {
 			select {
 			case item, ok := <-in:
@@ -84,7 +172,16 @@ func Sink[T any](ctx context.Context, p *Pipeline, in <-chan T, fn func(item T))
 					// Канал закрыт, данных больше нет.
 					return
 				}
+
+				atomic.AddInt64(&stats.In, 1)
+				p.fireItem(name, 1)
+				atomic.AddInt64(&stats.InFlight, 1)
+
 				fn(item)
+
+				atomic.AddInt64(&stats.InFlight, -1)
+				atomic.AddInt64(&stats.Out, 1)
+
 			case <-ctx.Done():
 				// Пайплайн остановлен.
 				return
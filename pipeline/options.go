@@ -0,0 +1,74 @@
+package pipeline
+
+// nodeConfig хранит настройки, общие для узлов пайплайна (Stage, Filter, Batch, OrderedFanOut и т.д.).
+type nodeConfig struct {
+	bufferSize   int
+	errorPolicy  ErrorPolicy
+	classifier   Classifier
+	consumerGone *ConsumerGone
+	overflow     OverflowPolicy
+	name         string
+}
+
+// NodeOption определяет тип для функциональных опций узлов пайплайна.
+type NodeOption func(*nodeConfig)
+
+// WithBuffer задает размер буфера для выходного канала узла.
+// По умолчанию канал небуферизован (размер 0).
+func WithBuffer(n int) NodeOption {
+	return func(c *nodeConfig) {
+		c.bufferSize = n
+	}
+}
+
+// WithErrorPolicy задает политику обработки ошибок воркера (StopOnError по умолчанию,
+// SkipOnError или RetryWithBackoff). Учитывается только FanOut; остальные узлы
+// считают любую ошибку fn фатальной.
+func WithErrorPolicy(policy ErrorPolicy) NodeOption {
+	return func(c *nodeConfig) {
+		c.errorPolicy = policy
+	}
+}
+
+// WithClassifier задает функцию, которая классифицирует ошибку воркера в конкретное
+// ErrorAction. Если задан, имеет приоритет над действием по умолчанию из ErrorPolicy.
+func WithClassifier(classifier Classifier) NodeOption {
+	return func(c *nodeConfig) {
+		c.classifier = classifier
+	}
+}
+
+// WithConsumerGone связывает узел с ConsumerGone: FanOut перестает читать вход и
+// писать в выход, как только сигнал сработает, не считая это ошибкой пайплайна.
+// Передайте один и тот же ConsumerGone узлам выше по потоку и в SinkWithCancel.
+func WithConsumerGone(gone *ConsumerGone) NodeOption {
+	return func(c *nodeConfig) {
+		c.consumerGone = gone
+	}
+}
+
+// WithOverflow задает политику переполнения выходного канала узла (см.
+// OverflowPolicy). По умолчанию используется блокирующая отправка.
+func WithOverflow(policy OverflowPolicy) NodeOption {
+	return func(c *nodeConfig) {
+		c.overflow = policy
+	}
+}
+
+// WithName задает стабильное имя стадии для логов, хуков жизненного цикла и
+// Pipeline.Stats(). Учитывается узлами Source, FanOut, Sink и SinkWithCancel; если
+// не задано, они получают авто-имя вида "<kind>-<N>". Остальные узлы имя не используют.
+func WithName(name string) NodeOption {
+	return func(c *nodeConfig) {
+		c.name = name
+	}
+}
+
+// applyNodeOptions собирает опции в nodeConfig.
+func applyNodeOptions(opts []NodeOption) nodeConfig {
+	var c nodeConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// indexedItem привязывает к элементу монотонно возрастающий порядковый номер,
+// по которому OrderedFanOut восстанавливает исходный порядок на выходе.
+type indexedItem[Out any] struct {
+	idx   uint64
+	value Out
+}
+
+// resultHeap — min-heap из indexedItem, упорядоченный по idx.
+type resultHeap[Out any] []indexedItem[Out]
+
+func (h resultHeap[Out]) Len() int            { return len(h) }
+func (h resultHeap[Out]) Less(i, j int) bool  { return h[i].idx < h[j].idx }
+func (h resultHeap[Out]) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap[Out]) Push(x interface{}) { *h = append(*h, x.(indexedItem[Out])) }
+func (h *resultHeap[Out]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// OrderedFanOut ведет себя как FanOut — читает из `in`, обрабатывает элементы
+// `workerCount` параллельными воркерами с помощью `fn` — но, в отличие от FanOut,
+// гарантирует, что результаты попадут в выходной канал в том же порядке, в котором
+// соответствующие им элементы были прочитаны из `in`.
+//
+// Каждому элементу на входе присваивается монотонно возрастающий номер. Воркеры
+// складывают пары (номер, результат) в min-heap, а отдельная горутина-диспетчер
+// извлекает их из heap строго по возрастанию номера, блокируясь до тех пор, пока
+// очередной ожидаемый элемент не окажется готов.
+func OrderedFanOut[In, Out any](ctx context.Context, p *Pipeline, in <-chan In, workerCount int, fn func(item In) (Out, error), opts ...NodeOption) <-chan Out {
+	cfg := applyNodeOptions(opts)
+	out := make(chan Out, cfg.bufferSize)
+
+	type indexedIn struct {
+		idx   uint64
+		value In
+	}
+
+	tagged := make(chan indexedIn)
+
+	// Присваиваем входным элементам порядковые номера.
+	p.Add(func() {
+		defer close(tagged)
+		var next uint64
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case tagged <- indexedIn{idx: next, value: item}:
+					next++
+				case <-ctx.Done():
+					return
+				case <-consumerGoneChan(cfg.consumerGone):
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	results := make(chan indexedItem[Out])
+
+	// Воркеры обрабатывают элементы параллельно, но помечают результат исходным idx,
+	// чтобы диспетчер мог восстановить порядок.
+	var wg sync.WaitGroup
+	p.Add(func() {
+		for i := 0; i < workerCount; i++ {
+			wg.Add(1)
+			p.Add(func() {
+				defer wg.Done()
+				for {
+					select {
+					case item, ok := <-tagged:
+						if !ok {
+							return
+						}
+
+						result, err := fn(item.value)
+						if err != nil {
+							p.Stop(err)
+							return
+						}
+
+						select {
+						case results <- indexedItem[Out]{idx: item.idx, value: result}:
+						case <-ctx.Done():
+							return
+						case <-consumerGoneChan(cfg.consumerGone):
+							return
+						}
+
+					case <-ctx.Done():
+						return
+					case <-consumerGoneChan(cfg.consumerGone):
+						return
+					}
+				}
+			})
+		}
+
+		wg.Wait()
+		close(results)
+	})
+
+	// Диспетчер копит результаты в heap и отдает их наружу строго по возрастанию idx,
+	// учитывая overflow-политику и сигнал ConsumerGone так же, как остальные узлы.
+	p.Add(func() {
+		defer close(out)
+
+		h := &resultHeap[Out]{}
+		heap.Init(h)
+		var nextOut uint64
+
+		drain := func() bool {
+			for h.Len() > 0 && (*h)[0].idx == nextOut {
+				next := heap.Pop(h).(indexedItem[Out])
+				if !sendWithOverflow(ctx, cfg.consumerGone, out, next.value, cfg.overflow) {
+					return false
+				}
+				nextOut++
+			}
+			return true
+		}
+
+		for {
+			select {
+			case item, ok := <-results:
+				if !ok {
+					// Входной поток воркеров исчерпан, но в heap могут оставаться
+					// готовые к выдаче результаты (они уже идут подряд).
+					drain()
+					return
+				}
+
+				heap.Push(h, item)
+
+				if !drain() {
+					return
+				}
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return out
+}
@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestOrderedFanOutPreservesOrderUnderSkew проверяет, что OrderedFanOut отдает
+// результаты строго в порядке входных элементов, даже когда воркеры завершают
+// их обработку в другом порядке (например, ранние элементы обрабатываются
+// дольше поздних).
+func TestOrderedFanOutPreservesOrderUnderSkew(t *testing.T) {
+	const n = 50
+
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < n; i++ {
+			in <- i
+		}
+	}()
+
+	out := OrderedFanOut(ctx, p, in, 8, func(item int) (int, error) {
+		// Чем меньше номер, тем дольше обработка - воркеры гарантированно
+		// закончат работу не в том порядке, в котором получили элементы.
+		time.Sleep(time.Duration(n-item) * time.Millisecond / 4)
+		return item, nil
+	})
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	if len(got) != n {
+		t.Fatalf("получено %d элементов, ожидалось %d", len(got), n)
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("нарушен порядок: got[%d] = %d, хотели %d", i, v, i)
+		}
+	}
+
+	p.Wait()
+}
+
+// TestOrderedFanOutConsumerGoneUnblocks - регрессионный тест: раньше сигнал
+// ConsumerGone нигде не проверялся внутри OrderedFanOut, поэтому дождаться
+// p.Wait() после него было невозможно - диспетчер навсегда блокировался на
+// отправке в `out`.
+func TestOrderedFanOutConsumerGoneUnblocks(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+	}()
+
+	gone := NewConsumerGone()
+	out := OrderedFanOut(ctx, p, in, 2, func(item int) (int, error) {
+		return item, nil
+	}, WithConsumerGone(gone))
+
+	// Читаем ровно один элемент, как медленный потребитель, а затем
+	// сообщаем, что нам больше ничего не нужно.
+	<-out
+	gone.Signal()
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("p.Wait() не вернулся после ConsumerGone.Signal() - OrderedFanOut завис")
+	}
+}
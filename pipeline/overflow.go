@@ -0,0 +1,70 @@
+package pipeline
+
+import "context"
+
+// OverflowPolicy определяет, что делать, когда выходной канал узла заполнен, а его
+// потребитель не успевает разбирать данные.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock - поведение по умолчанию: отправка блокируется до тех пор, пока
+	// потребитель не освободит место (как и было до появления этой опции).
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest отбрасывает новый элемент, если канал заполнен.
+	OverflowDropNewest
+	// OverflowDropOldest вытесняет из канала самый старый элемент, чтобы освободить
+	// место для нового. Имеет смысл только с буферизованным каналом (WithBuffer).
+	OverflowDropOldest
+)
+
+// sendWithOverflow отправляет value в out согласно policy. Отправка всегда
+// прерывается отменой ctx или сигналом ConsumerGone (если gone != nil).
+// Возвращает false, если отправка была прервана одним из этих сигналов, а не
+// значит, что значение обязательно было доставлено (DropNewest/DropOldest могут
+// отбросить его молча).
+func sendWithOverflow[T any](ctx context.Context, gone *ConsumerGone, out chan T, value T, policy OverflowPolicy) bool {
+	goneC := consumerGoneChan(gone)
+
+	switch policy {
+	case OverflowDropNewest:
+		select {
+		case out <- value:
+		case <-ctx.Done():
+			return false
+		case <-goneC:
+			return false
+		default:
+			// Канал заполнен - новый элемент молча отбрасывается.
+		}
+		return true
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case out <- value:
+				return true
+			case <-ctx.Done():
+				return false
+			case <-goneC:
+				return false
+			default:
+			}
+
+			// Места нет - вытесняем самый старый элемент и пробуем снова.
+			select {
+			case <-out:
+			default:
+			}
+		}
+
+	default: // OverflowBlock
+		select {
+		case out <- value:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-goneC:
+			return false
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStageOverflowDropNewest проверяет, что с WithOverflow(OverflowDropNewest)
+// и полным буфером новые элементы молча отбрасываются, а не блокируют узел, и
+// более ранние элементы, уже лежащие в буфере, остаются нетронутыми.
+func TestStageOverflowDropNewest(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	out := Stage(ctx, p, in, func(item int) (int, error) {
+		return item, nil
+	}, WithBuffer(1), WithOverflow(OverflowDropNewest))
+
+	go func() {
+		defer close(in)
+		// Буфер вмещает только 1 элемент - при отсутствии потребителя второй и
+		// третий должны быть отброшены, а не заблокировать отправку.
+		in <- 1
+		in <- 2
+		in <- 3
+	}()
+
+	// Даем узлу время положить первый элемент в буфер и попытаться (безуспешно)
+	// отправить остальные, прежде чем мы начнем читать.
+	time.Sleep(50 * time.Millisecond)
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("получили %v, ожидали только [1] (остальное должно быть отброшено DropNewest)", got)
+	}
+
+	p.Wait()
+}
+
+// TestStageOverflowDropOldest проверяет, что с WithOverflow(OverflowDropOldest)
+// при заполненном буфере самый старый элемент вытесняется, чтобы освободить
+// место для нового - наружу в итоге должны попасть более свежие значения.
+func TestStageOverflowDropOldest(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	out := Stage(ctx, p, in, func(item int) (int, error) {
+		return item, nil
+	}, WithBuffer(1), WithOverflow(OverflowDropOldest))
+
+	go func() {
+		defer close(in)
+		in <- 1
+		in <- 2
+		in <- 3
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	if len(got) != 1 || got[0] != 3 {
+		t.Fatalf("получили %v, ожидали только [3] (более старые значения должны быть вытеснены DropOldest)", got)
+	}
+
+	p.Wait()
+}
+
+// TestStageOverflowBlockIsDefault проверяет, что без WithOverflow (или с явным
+// OverflowBlock) ни один элемент не теряется - узел просто блокируется, пока
+// потребитель не заберет данные, как и до появления этой опции.
+func TestStageOverflowBlockIsDefault(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	out := Stage(ctx, p, in, func(item int) (int, error) {
+		return item, nil
+	})
+
+	go func() {
+		defer close(in)
+		for i := 1; i <= 3; i++ {
+			in <- i
+		}
+	}()
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	want := []int{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("получили %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("получили %v, ожидали %v", got, want)
+		}
+	}
+
+	p.Wait()
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"sync"
+	"time"
 )
 
 // Pipeline управляет выполнением набора горутин, обеспечивает graceful shutdown
@@ -12,15 +13,34 @@ type Pipeline struct {
 	wg     sync.WaitGroup
 	cancel context.CancelCauseFunc // Функция для отмены контекста с указанием причины
 	logger *log.Logger
+
+	metricsMu     sync.Mutex
+	fanOutMetrics []*FanOutMetrics
+
+	statsMu  sync.Mutex
+	stages   map[string]*StageStats
+	stageSeq int64
+
+	// Хуки жизненного цикла узлов, см. WithOnItem/WithOnError/WithOnStageStart/WithOnStageStop.
+	onItem       func(stage string, n int)
+	onError      func(stage string, err error)
+	onStageStart func(stage string)
+	onStageStop  func(stage string)
+
+	// shutdownTimeout используется RunWithSignals, см. WithShutdownTimeout.
+	shutdownTimeout time.Duration
 }
 
 // Option определяет тип для функциональных опций конструктора Pipeline
 type Option func(*Pipeline)
 
-// WithLogger задает кастомный логгер для пайплайна
+// WithLogger задает кастомный логгер для пайплайна. nil игнорируется, чтобы
+// остался логгер по умолчанию (пишущий в io.Discard).
 func WithLogger(logger *log.Logger) Option {
 	return func(p *Pipeline) {
-		p.logger = logger
+		if logger != nil {
+			p.logger = logger
+		}
 	}
 }
 
@@ -64,3 +84,24 @@ func (p *Pipeline) Stop(err error) {
 func (p *Pipeline) Wait() {
 	p.wg.Wait()
 }
+
+// registerFanOutMetrics регистрирует метрики узла FanOut, чтобы их можно было
+// получить позже через Pipeline.FanOutMetrics.
+func (p *Pipeline) registerFanOutMetrics(m *FanOutMetrics) {
+	p.metricsMu.Lock()
+	defer p.metricsMu.Unlock()
+	p.fanOutMetrics = append(p.fanOutMetrics, m)
+}
+
+// FanOutMetrics возвращает снимок счетчиков всех узлов FanOut, созданных в рамках
+// этого пайплайна, в порядке их создания.
+func (p *Pipeline) FanOutMetrics() []FanOutMetrics {
+	p.metricsMu.Lock()
+	defer p.metricsMu.Unlock()
+
+	snapshots := make([]FanOutMetrics, len(p.fanOutMetrics))
+	for i, m := range p.fanOutMetrics {
+		snapshots[i] = m.snapshot()
+	}
+	return snapshots
+}
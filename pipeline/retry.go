@@ -0,0 +1,184 @@
+package pipeline
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorAction описывает, как FanOut должен отреагировать на ошибку, возвращенную
+// воркером: остановить весь пайплайн, повторить попытку или пропустить элемент.
+type ErrorAction int
+
+const (
+	ActionFatal ErrorAction = iota
+	ActionRetry
+	ActionSkip
+)
+
+// Classifier классифицирует ошибку воркера в одно из действий ErrorAction.
+// Если не задан, действие определяется выбранным ErrorPolicy.
+type Classifier func(err error) ErrorAction
+
+// BackoffPolicy описывает параметры экспоненциального backoff с джиттером для
+// политики RetryWithBackoff.
+type BackoffPolicy struct {
+	MaxAttempts int           // максимальное число попыток обработки элемента, включая первую
+	Initial     time.Duration // задержка перед первым повтором
+	Max         time.Duration // верхняя граница задержки; 0 - без ограничения
+	Jitter      float64       // доля случайного разброса задержки, например 0.1 значит ±10%
+}
+
+type errorPolicyKind int
+
+const (
+	policyStopOnError errorPolicyKind = iota
+	policySkipOnError
+	policyRetryWithBackoff
+)
+
+// ErrorPolicy определяет действие FanOut по умолчанию для ошибок, не классифицированных
+// явно через Classifier. Нулевое значение эквивалентно StopOnError.
+type ErrorPolicy struct {
+	kind    errorPolicyKind
+	backoff BackoffPolicy
+}
+
+// StopOnError останавливает весь пайплайн при первой ошибке воркера. Это поведение
+// по умолчанию, совпадающее с прежним (до появления ErrorPolicy) поведением FanOut.
+func StopOnError() ErrorPolicy {
+	return ErrorPolicy{kind: policyStopOnError}
+}
+
+// SkipOnError пропускает элемент, на котором воркер вернул ошибку, и продолжает
+// обработку остальных элементов.
+func SkipOnError() ErrorPolicy {
+	return ErrorPolicy{kind: policySkipOnError}
+}
+
+// RetryWithBackoff повторяет обработку элемента с экспоненциальным backoff и
+// джиттером, пока не будет достигнут b.MaxAttempts; после этого элемент считается
+// неудавшимся и пропускается.
+func RetryWithBackoff(b BackoffPolicy) ErrorPolicy {
+	return ErrorPolicy{kind: policyRetryWithBackoff, backoff: b}
+}
+
+// action возвращает действие по умолчанию для данной политики.
+func (e ErrorPolicy) action() ErrorAction {
+	switch e.kind {
+	case policySkipOnError:
+		return ActionSkip
+	case policyRetryWithBackoff:
+		return ActionRetry
+	default:
+		return ActionFatal
+	}
+}
+
+// FailedItem описывает элемент, обработка которого окончательно не удалась:
+// пропущен по ErrorPolicy/Classifier либо исчерпал попытки RetryWithBackoff.
+type FailedItem[In any] struct {
+	Item In
+	Err  error
+}
+
+// FanOutMetrics содержит счетчики работы FanOut: число попыток обработки, повторов,
+// пропущенных и фатальных ошибок.
+type FanOutMetrics struct {
+	Attempts int64
+	Retries  int64
+	Skips    int64
+	Fatals   int64
+}
+
+func (m *FanOutMetrics) snapshot() FanOutMetrics {
+	return FanOutMetrics{
+		Attempts: atomic.LoadInt64(&m.Attempts),
+		Retries:  atomic.LoadInt64(&m.Retries),
+		Skips:    atomic.LoadInt64(&m.Skips),
+		Fatals:   atomic.LoadInt64(&m.Fatals),
+	}
+}
+
+// backoffDelay вычисляет задержку после провала попытки attempt (нумерация с 1) -
+// то есть перед попыткой attempt+1.
+func backoffDelay(b BackoffPolicy, attempt int) time.Duration {
+	delay := b.Initial * time.Duration(int64(1)<<uint(attempt-1))
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	if b.Jitter > 0 {
+		spread := float64(delay) * b.Jitter
+		delay += time.Duration((rand.Float64()*2 - 1) * spread)
+		if delay < 0 {
+			delay = 0
+		}
+	}
+	return delay
+}
+
+// sleepOrDone ждет истечения delay, но прерывается раньше, если ctx завершен.
+// Возвращает false, если ожидание было прервано отменой контекста.
+func sleepOrDone(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// runWithPolicy выполняет fn над item с учетом ErrorPolicy/Classifier: повторяет
+// вызов при ActionRetry, формирует FailedItem при ActionSkip (или после исчерпания
+// попыток RetryWithBackoff) и возвращает fatal-ошибку при ActionFatal.
+func runWithPolicy[In, Out any](ctx context.Context, item In, fn func(In) (Out, error), policy ErrorPolicy, classifier Classifier, metrics *FanOutMetrics) (result Out, failedItem *FailedItem[In], fatal error) {
+	attempt := 0
+	for {
+		attempt++
+		atomic.AddInt64(&metrics.Attempts, 1)
+
+		res, err := fn(item)
+		if err == nil {
+			return res, nil, nil
+		}
+
+		action := policy.action()
+		if classifier != nil {
+			action = classifier(err)
+		}
+
+		switch action {
+		case ActionFatal:
+			atomic.AddInt64(&metrics.Fatals, 1)
+			return result, nil, err
+
+		case ActionSkip:
+			atomic.AddInt64(&metrics.Skips, 1)
+			return result, &FailedItem[In]{Item: item, Err: err}, nil
+
+		default: // ActionRetry
+			if policy.kind != policyRetryWithBackoff || attempt >= policy.backoff.MaxAttempts {
+				// Ретраи не настроены или попытки исчерпаны - считаем элемент пропущенным.
+				atomic.AddInt64(&metrics.Skips, 1)
+				return result, &FailedItem[In]{Item: item, Err: err}, nil
+			}
+
+			atomic.AddInt64(&metrics.Retries, 1)
+			if !sleepOrDone(ctx, backoffDelay(policy.backoff, attempt)) {
+				return result, &FailedItem[In]{Item: item, Err: ctx.Err()}, nil
+			}
+		}
+	}
+}
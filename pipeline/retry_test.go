@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+// TestRunWithPolicyTransitions проверяет переходы ActionFatal/ActionSkip/ActionRetry
+// в runWithPolicy: кто останавливает пайплайн, кто отдает FailedItem, а кто
+// повторяет попытку нужное число раз.
+func TestRunWithPolicyTransitions(t *testing.T) {
+	t.Run("StopOnError останавливается с фатальной ошибкой", func(t *testing.T) {
+		metrics := &FanOutMetrics{}
+		_, failed, fatal := runWithPolicy(context.Background(), 1, func(int) (int, error) {
+			return 0, errBoom
+		}, StopOnError(), nil, metrics)
+
+		if fatal == nil {
+			t.Fatalf("ожидали fatal error, получили nil")
+		}
+		if failed != nil {
+			t.Fatalf("ожидали nil FailedItem при ActionFatal, получили %+v", failed)
+		}
+		if metrics.snapshot().Fatals != 1 {
+			t.Fatalf("ожидали Fatals=1, получили %d", metrics.Fatals)
+		}
+	})
+
+	t.Run("SkipOnError пропускает элемент без фатальной ошибки", func(t *testing.T) {
+		metrics := &FanOutMetrics{}
+		_, failed, fatal := runWithPolicy(context.Background(), 1, func(int) (int, error) {
+			return 0, errBoom
+		}, SkipOnError(), nil, metrics)
+
+		if fatal != nil {
+			t.Fatalf("ожидали nil fatal, получили %v", fatal)
+		}
+		if failed == nil || failed.Err != errBoom {
+			t.Fatalf("ожидали FailedItem с errBoom, получили %+v", failed)
+		}
+		if metrics.snapshot().Skips != 1 {
+			t.Fatalf("ожидали Skips=1, получили %d", metrics.Skips)
+		}
+	})
+
+	t.Run("RetryWithBackoff повторяет попытки до MaxAttempts и затем пропускает", func(t *testing.T) {
+		metrics := &FanOutMetrics{}
+		attempts := 0
+		policy := RetryWithBackoff(BackoffPolicy{MaxAttempts: 3, Initial: 0})
+
+		_, failed, fatal := runWithPolicy(context.Background(), 1, func(int) (int, error) {
+			attempts++
+			return 0, errBoom
+		}, policy, nil, metrics)
+
+		if fatal != nil {
+			t.Fatalf("ожидали nil fatal после исчерпания попыток, получили %v", fatal)
+		}
+		if attempts != 3 {
+			t.Fatalf("ожидали 3 попытки, получили %d", attempts)
+		}
+		if failed == nil || failed.Err != errBoom {
+			t.Fatalf("ожидали FailedItem с errBoom, получили %+v", failed)
+		}
+		snap := metrics.snapshot()
+		if snap.Attempts != 3 {
+			t.Fatalf("ожидали Attempts=3, получили %d", snap.Attempts)
+		}
+		if snap.Retries != 2 {
+			t.Fatalf("ожидали Retries=2, получили %d", snap.Retries)
+		}
+		if snap.Skips != 1 {
+			t.Fatalf("ожидали итоговый Skips=1, получили %d", snap.Skips)
+		}
+	})
+
+	t.Run("RetryWithBackoff успевает до исчерпания попыток", func(t *testing.T) {
+		metrics := &FanOutMetrics{}
+		attempts := 0
+		policy := RetryWithBackoff(BackoffPolicy{MaxAttempts: 5, Initial: 0})
+
+		result, failed, fatal := runWithPolicy(context.Background(), 1, func(int) (int, error) {
+			attempts++
+			if attempts < 2 {
+				return 0, errBoom
+			}
+			return 42, nil
+		}, policy, nil, metrics)
+
+		if fatal != nil || failed != nil {
+			t.Fatalf("ожидали успешный результат, получили fatal=%v failed=%+v", fatal, failed)
+		}
+		if result != 42 {
+			t.Fatalf("ожидали result=42, получили %d", result)
+		}
+		if attempts != 2 {
+			t.Fatalf("ожидали 2 попытки, получили %d", attempts)
+		}
+	})
+
+	t.Run("Classifier переопределяет действие ErrorPolicy по умолчанию", func(t *testing.T) {
+		metrics := &FanOutMetrics{}
+		classifier := func(err error) ErrorAction { return ActionSkip }
+
+		_, failed, fatal := runWithPolicy(context.Background(), 1, func(int) (int, error) {
+			return 0, errBoom
+		}, StopOnError(), classifier, metrics)
+
+		if fatal != nil {
+			t.Fatalf("classifier должен был отменить ActionFatal, получили %v", fatal)
+		}
+		if failed == nil {
+			t.Fatalf("ожидали FailedItem от classifier-управляемого ActionSkip")
+		}
+	})
+}
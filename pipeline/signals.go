@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// ErrShutdownTimeout возвращается RunWithSignals, если воркеры не успели
+// завершиться за отведенный WithShutdownTimeout таймаут после сигнала остановки.
+var ErrShutdownTimeout = errors.New("pipeline: воркеры не завершились в течение таймаута остановки")
+
+// WithShutdownTimeout задает, сколько RunWithSignals ждет штатного завершения
+// воркеров после первого сигнала, прежде чем вернуть ErrShutdownTimeout. d <= 0
+// означает "ждать неограниченно долго" (таймаут не сработает, но второй сигнал
+// все равно прервет ожидание).
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(p *Pipeline) {
+		p.shutdownTimeout = d
+	}
+}
+
+// RunWithSignals устанавливает обработчик сигналов ОС и ждет либо штатного
+// завершения пайплайна (p.Wait возвращается сам), либо сигнала из signals
+// (os.Interrupt, если список пуст), либо отмены ctx - пайплайн мог начать
+// остановку сам, например из-за p.Stop(err) на фатальной ошибке в узле, без
+// какого-либо сигнала ОС.
+//
+// По первому сигналу (или по отмене ctx) вызывается p.Stop(nil) - это
+// запускает graceful drain: узлы видят отмену контекста и перестают
+// производить новые данные, но то, что уже "в полете", дорабатывается и
+// штатно закрывает каналы. Если воркеры не успевают завершиться за
+// WithShutdownTimeout, либо приходит второй сигнал, RunWithSignals возвращает
+// ErrShutdownTimeout, не дожидаясь оставшихся воркеров.
+func RunWithSignals(ctx context.Context, p *Pipeline, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	done := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+
+	case <-sigCh:
+		// Первый сигнал - штатная остановка, даем воркерам дообработать "в полете".
+		p.Stop(nil)
+
+	case <-ctx.Done():
+		// Пайплайн остановился сам (например, p.Stop(err) из-за фатальной ошибки
+		// в узле) - реагируем так же, как на первый сигнал, и даем тот же
+		// таймаут на штатный дренаж, вместо того чтобы ждать p.Wait() бесконечно.
+		p.Stop(nil)
+	}
+
+	var deadline <-chan time.Time
+	if p.shutdownTimeout > 0 {
+		timer := time.NewTimer(p.shutdownTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	select {
+	case <-done:
+		return nil
+
+	case <-sigCh:
+		// Второй сигнал - пользователь не хочет ждать дольше.
+		return ErrShutdownTimeout
+
+	case <-deadline:
+		return ErrShutdownTimeout
+	}
+}
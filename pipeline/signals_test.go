@@ -0,0 +1,159 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestRunWithSignalsNormalDrain проверяет, что при штатном завершении
+// пайплайна (без сигналов и без отмены ctx) RunWithSignals возвращает nil, как
+// только p.Wait() сам вернулся.
+func TestRunWithSignalsNormalDrain(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	close(in)
+	Sink(ctx, p, in, func(int) {})
+
+	done := make(chan error, 1)
+	go func() { done <- RunWithSignals(ctx, p) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ожидали nil при штатном завершении, получили %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWithSignals не вернулся при штатном завершении пайплайна")
+	}
+}
+
+// TestRunWithSignalsFirstSignalThenDrain проверяет, что после первого сигнала
+// воркеры успевают штатно завершиться (Sink реагирует на отмену контекста), и
+// RunWithSignals возвращает nil, не дожидаясь второго сигнала или таймаута.
+func TestRunWithSignalsFirstSignalThenDrain(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	Sink(ctx, p, in, func(int) {})
+
+	done := make(chan error, 1)
+	go func() { done <- RunWithSignals(ctx, p, os.Interrupt) }()
+
+	// Даем горутинам время встать на select, затем шлем себе первый сигнал.
+	time.Sleep(50 * time.Millisecond)
+	selfSignal(t, os.Interrupt)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ожидали nil после штатного дренажа по первому сигналу, получили %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWithSignals не вернулся после первого сигнала и штатного дренажа")
+	}
+}
+
+// TestRunWithSignalsTimeoutWhenWorkerHangs проверяет, что если воркер
+// игнорирует отмену контекста и не завершается, RunWithSignals не ждет вечно:
+// по истечении WithShutdownTimeout после первого сигнала возвращается
+// ErrShutdownTimeout.
+func TestRunWithSignalsTimeoutWhenWorkerHangs(t *testing.T) {
+	p, ctx := New(context.Background(), WithShutdownTimeout(100*time.Millisecond))
+
+	in := make(chan int)
+	p.Add(func() {
+		// Воркер, который сознательно не следит за ctx.Done() - имитация
+		// зависшей обработки, которую graceful drain не может прервать.
+		<-in
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- RunWithSignals(ctx, p, os.Interrupt) }()
+
+	time.Sleep(50 * time.Millisecond)
+	selfSignal(t, os.Interrupt)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("ожидали ErrShutdownTimeout, получили %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWithSignals не вернулся по истечении WithShutdownTimeout")
+	}
+}
+
+// TestRunWithSignalsSecondSignalReturnsImmediately проверяет, что второй
+// сигнал обрывает ожидание штатного дренажа немедленно, не дожидаясь таймаута.
+func TestRunWithSignalsSecondSignalReturnsImmediately(t *testing.T) {
+	p, ctx := New(context.Background(), WithShutdownTimeout(time.Hour))
+
+	in := make(chan int)
+	p.Add(func() {
+		<-in
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- RunWithSignals(ctx, p, os.Interrupt) }()
+
+	time.Sleep(50 * time.Millisecond)
+	selfSignal(t, os.Interrupt)
+	time.Sleep(50 * time.Millisecond)
+	selfSignal(t, os.Interrupt)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("ожидали ErrShutdownTimeout после второго сигнала, получили %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWithSignals не вернулся после второго сигнала")
+	}
+}
+
+// TestRunWithSignalsReactsToCtxCancellation - регрессионный тест на сам
+// параметр ctx: если пайплайн останавливается сам по себе (p.Stop из-за
+// фатальной ошибки в узле), а не по сигналу ОС, RunWithSignals все равно не
+// должен ждать вечно - он обязан начать штатный дренаж так же, как по сигналу.
+func TestRunWithSignalsReactsToCtxCancellation(t *testing.T) {
+	p, ctx := New(context.Background(), WithShutdownTimeout(100*time.Millisecond))
+
+	in := make(chan int)
+	p.Add(func() {
+		// Зависший воркер - единственный способ завершиться здесь - это
+		// таймаут после того, как RunWithSignals заметит отмену ctx.
+		<-in
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- RunWithSignals(ctx, p) }()
+
+	time.Sleep(50 * time.Millisecond)
+	p.Stop(errors.New("фатальная ошибка узла"))
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrShutdownTimeout) {
+			t.Fatalf("ожидали ErrShutdownTimeout после отмены ctx без сигнала ОС, получили %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunWithSignals не заметил отмену ctx и завис, ожидая сигнал ОС")
+	}
+}
+
+// selfSignal шлет текущему процессу сигнал ОС - используется, чтобы
+// протестировать реакцию RunWithSignals на sigCh без внешних зависимостей.
+func selfSignal(t *testing.T, sig os.Signal) {
+	t.Helper()
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("os.FindProcess: %v", err)
+	}
+	if err := proc.Signal(sig); err != nil {
+		t.Fatalf("Signal(%v): %v", sig, err)
+	}
+}
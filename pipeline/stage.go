@@ -0,0 +1,78 @@
+package pipeline
+
+import "context"
+
+// Stage запускает узел 1:1 преобразования: читает элементы из `in`, применяет `fn`
+// и отправляет результат в выходной канал. В отличие от FanOut, здесь нет параллельных
+// воркеров — элементы обрабатываются строго по одному, в порядке поступления.
+func Stage[In, Out any](ctx context.Context, p *Pipeline, in <-chan In, fn func(item In) (Out, error), opts ...NodeOption) <-chan Out {
+	cfg := applyNodeOptions(opts)
+	out := make(chan Out, cfg.bufferSize)
+
+	p.Add(func() {
+		defer close(out)
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					// Входной канал закрыт, обрабатывать больше нечего.
+					return
+				}
+
+				result, err := fn(item)
+				if err != nil {
+					// Ошибка в трансформации считается фатальной для пайплайна.
+					p.Stop(err)
+					return
+				}
+
+				if !sendWithOverflow(ctx, cfg.consumerGone, out, result, cfg.overflow) {
+					return
+				}
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// Filter пропускает в выходной канал только те элементы `in`, для которых `keep`
+// возвращает true. Остальные элементы отбрасываются.
+func Filter[T any](ctx context.Context, p *Pipeline, in <-chan T, keep func(item T) bool, opts ...NodeOption) <-chan T {
+	cfg := applyNodeOptions(opts)
+	out := make(chan T, cfg.bufferSize)
+
+	p.Add(func() {
+		defer close(out)
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					return
+				}
+
+				if !keep(item) {
+					continue
+				}
+
+				if !sendWithOverflow(ctx, cfg.consumerGone, out, item, cfg.overflow) {
+					return
+				}
+
+			case <-ctx.Done():
+				return
+
+			case <-consumerGoneChan(cfg.consumerGone):
+				return
+			}
+		}
+	})
+
+	return out
+}
@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStageTransformsInOrder проверяет, что Stage применяет fn к каждому
+// элементу строго по одному и сохраняет порядок поступления на выходе.
+func TestStageTransformsInOrder(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 1; i <= 5; i++ {
+			in <- i
+		}
+	}()
+
+	out := Stage(ctx, p, in, func(item int) (int, error) {
+		return item * item, nil
+	})
+
+	want := []int{1, 4, 9, 16, 25}
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("получили %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("получили %v, ожидали %v", got, want)
+		}
+	}
+
+	p.Wait()
+}
+
+// TestStageStopsPipelineOnError проверяет, что ошибка fn останавливает весь
+// пайплайн через p.Stop, как и у остальных узлов без ErrorPolicy.
+func TestStageStopsPipelineOnError(t *testing.T) {
+	p, ctx := New(context.Background())
+	boom := errBoom
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		in <- 1
+		in <- 2
+	}()
+
+	out := Stage(ctx, p, in, func(item int) (int, error) {
+		if item == 2 {
+			return 0, boom
+		}
+		return item, nil
+	})
+
+	for range out {
+	}
+
+	p.Wait()
+
+	if got := context.Cause(ctx); got != boom {
+		t.Fatalf("ожидали, что ctx отменен с причиной %v, получили %v", boom, got)
+	}
+}
+
+// TestFilterKeepsOnlyMatching проверяет, что Filter пропускает дальше только
+// элементы, для которых keep вернул true.
+func TestFilterKeepsOnlyMatching(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 1; i <= 6; i++ {
+			in <- i
+		}
+	}()
+
+	out := Filter(ctx, p, in, func(item int) bool {
+		return item%2 == 0
+	})
+
+	want := []int{2, 4, 6}
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("получили %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("получили %v, ожидали %v", got, want)
+		}
+	}
+
+	p.Wait()
+}
+
+// TestBatchFlushesBySize проверяет, что Batch накапливает ровно `size`
+// элементов перед отправкой и отправляет неполный остаток при закрытии входа.
+func TestBatchFlushesBySize(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 1; i <= 7; i++ {
+			in <- i
+		}
+	}()
+
+	out := Batch(ctx, p, in, 3, 0)
+
+	var got [][]int
+	for batch := range out {
+		got = append(got, batch)
+	}
+
+	want := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if len(got) != len(want) {
+		t.Fatalf("получили %v, ожидали %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("батч %d: получили %v, ожидали %v", i, got[i], want[i])
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("батч %d: получили %v, ожидали %v", i, got[i], want[i])
+			}
+		}
+	}
+
+	p.Wait()
+}
+
+// TestBatchFlushesByTimeout проверяет, что неполный батч отправляется по
+// истечении flushInterval, не дожидаясь, пока наберется `size` элементов.
+func TestBatchFlushesByTimeout(t *testing.T) {
+	p, ctx := New(context.Background())
+
+	in := make(chan int)
+	out := Batch(ctx, p, in, 10, 30*time.Millisecond)
+
+	go func() {
+		in <- 1
+		in <- 2
+		// Меньше size=10 - батч должен флашнуться по таймауту, а не по размеру.
+	}()
+
+	select {
+	case got, ok := <-out:
+		if !ok || len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Fatalf("получили (%v, %v), ожидали ([1 2], true)", got, ok)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("не дождались флаша батча по таймауту")
+	}
+
+	close(in)
+	p.Wait()
+}
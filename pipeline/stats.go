@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// StageStats - это счетчики одного именованного узла пайплайна (стадии), которые
+// обновляются атомарно прямо внутри select-циклов Source/FanOut/Sink.
+type StageStats struct {
+	In       int64 // элементов получено на входе стадии
+	Out      int64 // элементов успешно отправлено на выход стадии
+	Errors   int64 // ошибок, сообщенных стадией
+	InFlight int64 // элементов, находящихся в обработке прямо сейчас
+}
+
+func (s *StageStats) snapshot() StageStats {
+	return StageStats{
+		In:       atomic.LoadInt64(&s.In),
+		Out:      atomic.LoadInt64(&s.Out),
+		Errors:   atomic.LoadInt64(&s.Errors),
+		InFlight: atomic.LoadInt64(&s.InFlight),
+	}
+}
+
+// stage возвращает счетчики для стадии с данным именем, создавая их при первом
+// обращении.
+func (p *Pipeline) stage(name string) *StageStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	if p.stages == nil {
+		p.stages = make(map[string]*StageStats)
+	}
+	s, ok := p.stages[name]
+	if !ok {
+		s = &StageStats{}
+		p.stages[name] = s
+	}
+	return s
+}
+
+// Stats возвращает снимок счетчиков всех стадий пайплайна, ключ - имя стадии
+// (см. WithName).
+func (p *Pipeline) Stats() map[string]StageStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+
+	snapshots := make(map[string]StageStats, len(p.stages))
+	for name, s := range p.stages {
+		snapshots[name] = s.snapshot()
+	}
+	return snapshots
+}
+
+// nextStageID выдает уникальный номер для автоматического имени стадии, если
+// WithName не был задан явно.
+func (p *Pipeline) nextStageID() int64 {
+	return atomic.AddInt64(&p.stageSeq, 1)
+}
+
+// stageName возвращает имя, заданное через WithName, либо генерирует стабильное
+// авто-имя вида "<kind>-<N>", уникальное в рамках пайплайна.
+func stageName(p *Pipeline, cfg nodeConfig, kind string) string {
+	if cfg.name != "" {
+		return cfg.name
+	}
+	return fmt.Sprintf("%s-%d", kind, p.nextStageID())
+}